@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package tail
+
+import "os"
+
+// openFile opens name for tailing.
+func openFile(name string) (*os.File, error) {
+	return os.Open(name)
+}
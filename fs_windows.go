@@ -0,0 +1,51 @@
+//go:build windows
+// +build windows
+
+package tail
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// getFileName returns the current path of the open file f.
+//
+// On Windows a renamed or deleted file's original path is no longer valid,
+// so we ask the kernel for the final path of the handle instead, the same
+// way getFileName on darwin uses F_GETPATH and getFileName on Linux/BSD
+// reads /proc/*/fd/*.
+func getFileName(f *os.File) (string, error) {
+	h := windows.Handle(f.Fd())
+	buf := make([]uint16, windows.MAX_PATH)
+	n, err := windows.GetFinalPathNameByHandle(h, &buf[0], uint32(len(buf)), 0)
+	if err != nil {
+		return "", fmt.Errorf("tail: fail to get path of fd: %w", err)
+	}
+	return windows.UTF16ToString(buf[:n]), nil
+}
+
+// openFile opens name for tailing, sharing delete access with other
+// processes so that log rotators can rename or remove the file while we
+// still hold it open. os.Open does not request FILE_SHARE_DELETE, which
+// would otherwise block rotation on Windows.
+func openFile(name string) (*os.File, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	h, err := windows.CreateFile(
+		p,
+		windows.GENERIC_READ,
+		windows.FILE_SHARE_DELETE|windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE,
+		nil,
+		windows.OPEN_EXISTING,
+		windows.FILE_ATTRIBUTE_NORMAL,
+		0,
+	)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(h), name), nil
+}
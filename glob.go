@@ -0,0 +1,332 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	fsnotify "github.com/fsnotify/fsnotify"
+)
+
+// ErrScatteredFiles is returned by NewTailGlob and NewTailFilesWithOptions
+// when the files to tail are not all in the same parent directory. Both
+// watch their parent directory just once with fsnotify, rather than one
+// watch per file, so that tailing hundreds of log files does not exhaust
+// the OS's inotify watch limit; this only works when every file shares a
+// single parent directory.
+var ErrScatteredFiles = errors.New("tail: files are scattered across more than one directory")
+
+// NewTailGlob starts tailing every file that currently matches pattern, and
+// automatically starts tailing new files created in pattern's directory
+// that also match it, merging lines from every matched file onto a single
+// Lines channel. Each emitted Line's Filename field identifies which file
+// it came from.
+//
+// pattern follows the syntax of filepath.Match. All of its matches must
+// resolve within a single parent directory; otherwise NewTailGlob returns
+// ErrScatteredFiles.
+//
+// Since NewTailGlob multiplexes many files, Options.Location does not
+// apply: there is no single resume point, so every initially matched file
+// starts from its end and every file picked up later starts from its
+// beginning, the same as when Location is unset.
+//
+// If opts.MustExist is set, NewTailGlob returns an error immediately when
+// pattern has no matches yet, instead of waiting for a matching file to be
+// created. If opts.NoFollow is set, each file is read to EOF and then
+// dropped instead of being followed for new lines. Options.NoReOpen does
+// not apply: picking up a replacement file under a name that matches
+// pattern is exactly what NewTailGlob is for, so it always "re-opens".
+func NewTailGlob(pattern string, opts Options) (*Tail, error) {
+	pattern, err := filepath.Abs(pattern)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MustExist && len(matches) == 0 {
+		return nil, fmt.Errorf("tail: no file matches %s", pattern)
+	}
+	dir := filepath.Dir(pattern)
+	if len(matches) > 0 {
+		// the directory part of pattern may itself contain wildcards.
+		dir = filepath.Dir(matches[0])
+	}
+	for _, name := range matches {
+		if filepath.Dir(name) != dir {
+			return nil, ErrScatteredFiles
+		}
+	}
+
+	base := filepath.Base(pattern)
+	match := func(name string) bool {
+		ok, err := filepath.Match(base, filepath.Base(name))
+		return err == nil && ok
+	}
+	return newTailGlob(dir, match, matches, opts)
+}
+
+// NewTailFilesWithOptions starts tailing every file named in filenames,
+// merging their lines onto a single Lines channel. Unlike NewTailGlob, no
+// other files are picked up automatically; each emitted Line's Filename
+// field identifies which of filenames it came from.
+//
+// All of filenames must resolve within a single parent directory;
+// otherwise NewTailFilesWithOptions returns ErrScatteredFiles.
+//
+// Like NewTailGlob, Options.Location does not apply here: every file in
+// filenames that already exists starts from its end, and one that is
+// created later starts from its beginning, regardless of Location.
+//
+// If opts.MustExist is set, NewTailFilesWithOptions returns an error
+// immediately if any of filenames does not exist yet, instead of waiting
+// for it to be created. If opts.NoFollow is set, each file is read to EOF
+// and then dropped instead of being followed for new lines.
+// Options.NoReOpen does not apply: a file named in filenames that is
+// renamed or removed stops being tailed, and a new file created under the
+// same name is picked up from its beginning.
+func NewTailFilesWithOptions(filenames []string, opts Options) (*Tail, error) {
+	if len(filenames) == 0 {
+		return nil, errors.New("tail: no files given")
+	}
+
+	abs := make([]string, len(filenames))
+	set := make(map[string]bool, len(filenames))
+	var dir string
+	for i, name := range filenames {
+		a, err := filepath.Abs(name)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			dir = filepath.Dir(a)
+		} else if filepath.Dir(a) != dir {
+			return nil, ErrScatteredFiles
+		}
+		if opts.MustExist {
+			if _, err := os.Stat(a); err != nil {
+				return nil, err
+			}
+		}
+		abs[i] = a
+		set[a] = true
+	}
+
+	match := func(name string) bool { return set[name] }
+	return newTailGlob(dir, match, abs, opts)
+}
+
+// globTail multiplexes a set of files within a single directory onto the
+// Lines channel of a *Tail. Each file is read by the same *tail used by
+// the single-file path (see tail.go), so truncation detection, rate
+// limiting, and Num/SeekInfo bookkeeping only need to be correct in one
+// place.
+type globTail struct {
+	parent  *Tail
+	match   func(name string) bool
+	watcher *fsnotify.Watcher
+
+	mu    sync.Mutex
+	files map[string]*tail
+}
+
+func newTailGlob(dir string, match func(name string) bool, initial []string, opts Options) (*Tail, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan *Line, linesCapacity)
+	errs := make(chan error, errorsCapacity)
+	parent := &Tail{
+		Lines:  lines,
+		Errors: errs,
+		opts:   opts,
+		lines:  lines,
+		errors: errs,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	g := &globTail{
+		parent:  parent,
+		match:   match,
+		watcher: watcher,
+		files:   make(map[string]*tail),
+	}
+
+	for _, name := range initial {
+		g.startFile(name, io.SeekEnd)
+	}
+
+	parent.wg.Add(1)
+	go func() {
+		defer parent.wg.Done()
+		defer watcher.Close()
+		g.run()
+	}()
+	go parent.wait()
+
+	return parent, nil
+}
+
+// run watches the directory and dispatches create/write/remove events to
+// the files being tailed.
+func (g *globTail) run() {
+	for {
+		select {
+		case event, ok := <-g.watcher.Events:
+			if !ok {
+				return
+			}
+			switch {
+			case event.Op.Has(fsnotify.Create):
+				if g.match(event.Name) {
+					g.startFile(event.Name, io.SeekStart)
+				}
+			case event.Op.Has(fsnotify.Remove), event.Op.Has(fsnotify.Rename):
+				g.stopFile(event.Name)
+			case event.Op.Has(fsnotify.Write):
+				g.notifyFile(event.Name)
+			}
+		case err, ok := <-g.watcher.Errors:
+			if !ok {
+				return
+			}
+			select {
+			case g.parent.errors <- err:
+			case <-g.parent.ctx.Done():
+				return
+			}
+		case <-g.parent.ctx.Done():
+			return
+		}
+	}
+}
+
+// startFile begins tailing name from seek, unless it is already being
+// tailed.
+func (g *globTail) startFile(name string, seek int) {
+	g.mu.Lock()
+	_, exists := g.files[name]
+	g.mu.Unlock()
+	if exists {
+		return
+	}
+
+	file, err := openFile(name)
+	if err != nil {
+		// the file disappeared between the directory event and our open;
+		// a Remove event, if any, will clean it up.
+		return
+	}
+	if _, err := file.Seek(0, seek); err != nil {
+		file.Close()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(g.parent.ctx)
+	ft := &tail{
+		parent:  g.parent,
+		name:    name,
+		file:    file,
+		reader:  g.parent.newReader(ctxReader{ctx: ctx, r: file}),
+		limiter: newRateLimiter(g.parent.opts),
+		ctx:     ctx,
+		cancel:  cancel,
+		notify:  make(chan struct{}, 1),
+	}
+
+	g.mu.Lock()
+	g.files[name] = ft
+	g.mu.Unlock()
+
+	g.parent.wg.Add(1)
+	go func() {
+		defer g.parent.wg.Done()
+		defer file.Close()
+		g.runFile(ft)
+	}()
+}
+
+// stopFile reaps the goroutine tailing name, if any.
+func (g *globTail) stopFile(name string) {
+	g.mu.Lock()
+	ft, ok := g.files[name]
+	delete(g.files, name)
+	g.mu.Unlock()
+	if ok {
+		ft.cancel()
+	}
+}
+
+// notifyFile wakes the goroutine tailing name so it retries reading after
+// hitting EOF.
+func (g *globTail) notifyFile(name string) {
+	g.mu.Lock()
+	ft, ok := g.files[name]
+	g.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case ft.notify <- struct{}{}:
+	default:
+	}
+}
+
+// runFile reads lines from ft until its context is canceled, using the
+// same restrict/readLines pair runFile (tail.go) uses for the single-file
+// path; only the EOF-retry and NoFollow wiring below are glob-specific,
+// since ft has no watcher of its own to wait on for new lines.
+func (g *globTail) runFile(ft *tail) {
+	for {
+		if err := ft.restrict(); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				select {
+				case g.parent.errors <- err:
+				case <-ft.ctx.Done():
+				}
+			}
+			return
+		}
+		err := ft.readLines()
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			if g.parent.opts.NoFollow {
+				// caught up with the file and not following: stop here,
+				// like plain cat.
+				g.mu.Lock()
+				delete(g.files, ft.name)
+				g.mu.Unlock()
+				return
+			}
+			select {
+			case <-ft.notify:
+				continue
+			case <-ft.ctx.Done():
+				return
+			}
+		}
+		if !errors.Is(err, context.Canceled) {
+			select {
+			case g.parent.errors <- err:
+			case <-ft.ctx.Done():
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,135 @@
+package tail
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTailGlob(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	a := filepath.Join(tmpdir, "a.log")
+	b := filepath.Join(tmpdir, "b.log")
+	if err := os.WriteFile(a, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := NewTailGlob(filepath.Join(tmpdir, "*.log"), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+
+	// wait for the initial match to be picked up.
+	time.Sleep(100 * time.Millisecond)
+
+	af, err := os.OpenFile(a, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer af.Close()
+	if _, err := af.WriteString("from a\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// b.log is created after NewTailGlob: it should be picked up
+	// automatically via the directory's Create event, and read from the
+	// start since it is new rather than pre-existing.
+	bf, err := os.OpenFile(b, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bf.Close()
+	if _, err := bf.WriteString("from b\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	timer := time.NewTimer(5 * time.Second)
+	defer timer.Stop()
+	for len(got) < 2 {
+		select {
+		case line := <-tail.Lines:
+			got[line.Filename] = line.Text
+		case err := <-tail.Errors:
+			t.Fatalf("unexpected error: %v", err)
+		case <-timer.C:
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+
+	if got[a] != "from a\n" {
+		t.Errorf("got %q for %s, want %q", got[a], a, "from a\n")
+	}
+	if got[b] != "from b\n" {
+		t.Errorf("got %q for %s, want %q", got[b], b, "from b\n")
+	}
+
+	// removing a matched file reaps its goroutine instead of leaving it
+	// blocked forever.
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestTailGlob_ScatteredFiles(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	sub := filepath.Join(tmpdir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	a := filepath.Join(tmpdir, "a.log")
+	b := filepath.Join(sub, "b.log")
+	if err := os.WriteFile(a, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewTailFilesWithOptions([]string{a, b}, Options{})
+	if !errors.Is(err, ErrScatteredFiles) {
+		t.Fatalf("got %v, want ErrScatteredFiles", err)
+	}
+}
+
+func TestTailGlob_Truncate(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+	if err := os.WriteFile(filename, []byte("aaaaaaaaaa\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := NewTailFilesWithOptions([]string{filename}, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	// truncate in place, without renaming: no directory Create/Rename
+	// event tells globTail about this, so it must notice the shrink
+	// itself via restrict, the same as the single-file path.
+	if err := os.WriteFile(filename, []byte("bb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	timer := time.NewTimer(5 * time.Second)
+	defer timer.Stop()
+	select {
+	case line := <-tail.Lines:
+		if line.Text != "bb\n" {
+			t.Errorf("got %q, want %q", line.Text, "bb\n")
+		}
+	case err := <-tail.Errors:
+		t.Fatalf("unexpected error: %v", err)
+	case <-timer.C:
+		t.Fatal("timed out waiting for the post-truncation line")
+	}
+}
@@ -0,0 +1,137 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OnRateLimit controls what happens to a line received while a file's rate
+// limit is exceeded.
+type OnRateLimit int
+
+const (
+	// Block waits until the leaky bucket has a free token before sending
+	// the line. This is the zero value.
+	Block OnRateLimit = iota
+
+	// Drop silently discards the line.
+	Drop
+
+	// DropWithNotice discards the line, and once the bucket has a free
+	// token again, emits one synthesized line reporting how many lines
+	// were suppressed in the meantime.
+	DropWithNotice
+)
+
+// rateLimiter is a per-file leaky bucket enforcing Options.LimitRate.
+type rateLimiter struct {
+	capacity     uint64
+	fillInterval time.Duration
+
+	mu         sync.Mutex
+	count      uint64
+	lastFill   time.Time
+	suppressed uint64
+}
+
+// newRateLimiter returns a rateLimiter for opts, or nil if opts does not
+// configure a rate limit.
+func newRateLimiter(opts Options) *rateLimiter {
+	if opts.LimitRate == 0 {
+		return nil
+	}
+	capacity := opts.BurstSize
+	if capacity == 0 {
+		capacity = opts.LimitRate
+	}
+	return &rateLimiter{
+		capacity:     capacity,
+		fillInterval: time.Second / time.Duration(opts.LimitRate),
+		count:        capacity,
+		lastFill:     time.Now(),
+	}
+}
+
+// refill adds one token per fillInterval elapsed since lastFill, capped at
+// capacity. The caller must hold r.mu.
+func (r *rateLimiter) refill() {
+	elapsed := time.Since(r.lastFill)
+	if elapsed < r.fillInterval {
+		return
+	}
+	tokens := uint64(elapsed / r.fillInterval)
+	r.count += tokens
+	if r.count > r.capacity {
+		r.count = r.capacity
+	}
+	r.lastFill = r.lastFill.Add(time.Duration(tokens) * r.fillInterval)
+}
+
+// wait blocks until a token is available, then consumes it. It returns
+// ctx.Err() if ctx is canceled first, so a blocked tail doesn't keep a
+// Close from returning.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		r.refill()
+		if r.count > 0 {
+			r.count--
+			r.mu.Unlock()
+			return nil
+		}
+		r.mu.Unlock()
+		select {
+		case <-time.After(r.fillInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// take reports whether a token was available, consuming it if so. If it
+// returns false the line is recorded as suppressed. If it returns true, it
+// also reports how many lines were suppressed since the last success, so
+// the caller can emit a recovery notice.
+func (r *rateLimiter) take() (ok bool, suppressed uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refill()
+	if r.count == 0 {
+		r.suppressed++
+		return false, 0
+	}
+	r.count--
+	suppressed, r.suppressed = r.suppressed, 0
+	return true, suppressed
+}
+
+// rateLimited applies lim to text, reporting whether text itself should be
+// emitted (emit), and, if lim just recovered from dropping lines, a
+// suppression notice to emit ahead of it (notice, non-empty only then).
+// The notice is a synthetic record that was never read from the file, so
+// unlike text it carries no Num or SeekInfo of its own; callers must not
+// advance or stamp either using text's position.
+// lim may be nil, meaning no rate limit is configured. It returns ctx.Err()
+// if ctx is canceled while blocked waiting for a token.
+func rateLimited(ctx context.Context, opts Options, lim *rateLimiter, text string) (notice string, emit bool, err error) {
+	if lim == nil {
+		return "", true, nil
+	}
+	if opts.OnRateLimit == Block {
+		if err := lim.wait(ctx); err != nil {
+			return "", false, err
+		}
+		return "", true, nil
+	}
+
+	ok, suppressed := lim.take()
+	if !ok {
+		return "", false, nil
+	}
+	if suppressed > 0 && opts.OnRateLimit == DropWithNotice {
+		notice = fmt.Sprintf("…rate-limited, %d lines suppressed…", suppressed)
+	}
+	return notice, true, nil
+}
@@ -0,0 +1,192 @@
+package tail
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_BurstThenDrop(t *testing.T) {
+	t.Parallel()
+	opts := Options{LimitRate: 100, BurstSize: 2, OnRateLimit: Drop}
+	lim := newRateLimiter(opts)
+
+	// the burst is consumed without dropping anything.
+	for i := 0; i < 2; i++ {
+		notice, emit, err := rateLimited(context.Background(), opts, lim, "line")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if notice != "" || !emit {
+			t.Fatalf("got notice=%q emit=%v, want \"\", true", notice, emit)
+		}
+	}
+
+	// the bucket is now empty: the next line is dropped.
+	notice, emit, err := rateLimited(context.Background(), opts, lim, "line")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notice != "" || emit {
+		t.Errorf("got notice=%q emit=%v, want \"\", false", notice, emit)
+	}
+}
+
+func TestRateLimiter_DropWithNotice(t *testing.T) {
+	t.Parallel()
+	opts := Options{LimitRate: 100, BurstSize: 1, OnRateLimit: DropWithNotice}
+	lim := newRateLimiter(opts)
+
+	if notice, emit, err := rateLimited(context.Background(), opts, lim, "a"); err != nil || notice != "" || !emit {
+		t.Fatalf("got %q, %v, %v, want \"\", true, nil", notice, emit, err)
+	}
+
+	// the bucket is empty: drop and record it as suppressed.
+	if notice, emit, err := rateLimited(context.Background(), opts, lim, "b"); err != nil || notice != "" || emit {
+		t.Fatalf("got %q, %v, %v, want \"\", false, nil", notice, emit, err)
+	}
+	if notice, emit, err := rateLimited(context.Background(), opts, lim, "c"); err != nil || notice != "" || emit {
+		t.Fatalf("got %q, %v, %v, want \"\", false, nil", notice, emit, err)
+	}
+
+	// wait for the bucket to refill, then expect a recovery notice ahead
+	// of the next line, with the line itself still emitted.
+	time.Sleep(2 * lim.fillInterval)
+	notice, emit, err := rateLimited(context.Background(), opts, lim, "d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notice == "" || !emit {
+		t.Fatalf("got notice=%q emit=%v, want a non-empty notice and emit=true", notice, emit)
+	}
+	t.Logf("notice: %s", notice)
+}
+
+func TestRateLimiter_Block(t *testing.T) {
+	t.Parallel()
+	opts := Options{LimitRate: 100, BurstSize: 1, OnRateLimit: Block}
+	lim := newRateLimiter(opts)
+
+	if notice, emit, err := rateLimited(context.Background(), opts, lim, "a"); err != nil || notice != "" || !emit {
+		t.Fatalf("got %q, %v, %v, want \"\", true, nil", notice, emit, err)
+	}
+
+	// the bucket is empty: this call blocks until it refills instead of
+	// dropping the line.
+	start := time.Now()
+	notice, emit, err := rateLimited(context.Background(), opts, lim, "b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < lim.fillInterval {
+		t.Errorf("returned after %v, want at least %v", elapsed, lim.fillInterval)
+	}
+	if notice != "" || !emit {
+		t.Fatalf("got notice=%q emit=%v, want \"\", true", notice, emit)
+	}
+}
+
+func TestRateLimiter_BlockRespectsContext(t *testing.T) {
+	t.Parallel()
+	opts := Options{LimitRate: 1, BurstSize: 1, OnRateLimit: Block}
+	lim := newRateLimiter(opts)
+
+	// drain the single token so the next call would otherwise block for a
+	// full second.
+	if _, _, err := rateLimited(context.Background(), opts, lim, "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, _, err := rateLimited(ctx, opts, lim, "b")
+	if err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > lim.fillInterval {
+		t.Errorf("blocked for %v, want it to return promptly after ctx is canceled", elapsed)
+	}
+}
+
+func TestRateLimiter_DropWithNoticeDoesNotShareLinePosition(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+	if err := os.WriteFile(filename, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{
+		Location:    &SeekInfo{Whence: io.SeekStart},
+		LimitRate:   1000,
+		BurstSize:   1,
+		OnRateLimit: DropWithNotice,
+	}
+	tail, err := NewTailFileWithOptions(filename, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// "a" consumes the single burst token; "b" and "c" are dropped and
+	// counted as suppressed while the bucket is empty; by the time "d" is
+	// written the bucket has refilled, so it arrives with a recovery
+	// notice ahead of it.
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := file.WriteString(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, err := file.WriteString("d\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	recv := func() *Line {
+		t.Helper()
+		select {
+		case line := <-tail.Lines:
+			return line
+		case err := <-tail.Errors:
+			t.Fatalf("unexpected error: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a line")
+		}
+		return nil
+	}
+
+	a := recv()
+	if a.Text != "a\n" || a.Num != 1 || a.SeekInfo.Offset != 2 {
+		t.Fatalf("got %+v, want text=%q num=1 offset=2", a, "a\n")
+	}
+
+	notice := recv()
+	if notice.Text == "d\n" {
+		t.Fatal("got the real line where the suppression notice was expected")
+	}
+	if notice.Num != 0 || notice.SeekInfo != (SeekInfo{}) {
+		t.Errorf("notice got Num=%d SeekInfo=%+v, want the zero value for both: "+
+			"it is synthetic and must not be mistaken for a checkpoint past the next real line",
+			notice.Num, notice.SeekInfo)
+	}
+
+	d := recv()
+	if d.Text != "d\n" || d.Num != 4 || d.SeekInfo.Offset != 8 {
+		t.Fatalf("got %+v, want text=%q num=4 offset=8", d, "d\n")
+	}
+}
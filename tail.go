@@ -25,6 +25,27 @@ const (
 type Line struct {
 	Text string
 	Time time.Time
+
+	// Filename is the path of the file the line was read from. It is only
+	// set when the Tail was created with NewTailGlob or
+	// NewTailFilesWithOptions, which multiplex lines from several files onto
+	// a single Lines channel; it is empty otherwise.
+	Filename string
+
+	// Num is the 1-based number of this line since tailing started, or
+	// since the file was last rotated or truncated.
+	Num int
+
+	// SeekInfo is the position immediately after this line in the current
+	// file. It can be stored and passed back as Options.Location to resume
+	// tailing without losing or duplicating lines across restarts.
+	SeekInfo SeekInfo
+}
+
+// SeekInfo identifies a position within a file being tailed.
+type SeekInfo struct {
+	Offset int64
+	Whence int
 }
 
 // Tail tails a file.
@@ -46,17 +67,75 @@ type Options struct {
 	// MaxBytesLine is maximum length of lines in bytes.
 	// If it is zero, there is no limit.
 	MaxBytesLine int64
+
+	// PollInterval makes Tail watch the file by periodically polling it with
+	// os.Stat instead of using file-system change notifications. It is useful
+	// on platforms or file systems (NFS, SMB) where rename/remove events are
+	// not delivered reliably. If it is zero, the platform default is used:
+	// file-system notifications everywhere except Windows, which always polls.
+	PollInterval time.Duration
+
+	// LimitRate is the maximum number of lines per second sent on Lines.
+	// If it is zero, there is no limit.
+	LimitRate uint64
+
+	// BurstSize is the number of lines that may be sent in a single burst
+	// before LimitRate starts throttling. If it is zero, it defaults to
+	// LimitRate. It has no effect when LimitRate is zero.
+	BurstSize uint64
+
+	// OnRateLimit controls what happens to lines received while LimitRate
+	// is exceeded. The zero value is Block.
+	OnRateLimit OnRateLimit
+
+	// Location, if set, resumes tailing from a previously recorded
+	// SeekInfo instead of seeking to the end of the file. This is essential
+	// for shippers that checkpoint their progress and must not lose or
+	// duplicate lines across restarts.
+	Location *SeekInfo
+
+	// MustExist makes NewTailFileWithOptions return an error immediately if
+	// filename does not exist yet, instead of waiting for it to be created.
+	MustExist bool
+
+	// NoReOpen stops Tail from keeping up across log rotation: it exits
+	// cleanly once the original file is gone, instead of reopening a newly
+	// created file in its place. The zero value keeps tailing across
+	// rotation, matching prior versions' behavior.
+	NoReOpen bool
+
+	// NoFollow stops Tail once it has caught up to the end of the file,
+	// instead of waiting for new lines: it reads to EOF and closes Lines,
+	// much like plain cat -- useful for one-shot processing of a rotated
+	// segment. The zero value follows the file forever, matching prior
+	// versions' behavior.
+	NoFollow bool
 }
 
+// tail reads lines from a single open file, applying rate limiting and
+// truncation detection; it is shared by the single-file path (runFile,
+// below) and by globTail, which multiplexes many of them. name is the
+// Line.Filename to tag emitted lines with; it is empty outside of
+// TailGlob. watcher and cancel's relationship to rotation are only used
+// by the single-file path.
 type tail struct {
 	parent *Tail
 
+	name    string
 	file    *os.File
 	reader  *bufio.Reader
-	watcher *fsnotify.Watcher
+	watcher watcher
+	limiter *rateLimiter
 	buf     bytes.Buffer
+	offset  int64
+	lineNum int
 	ctx     context.Context
 	cancel  context.CancelFunc
+
+	// notify wakes a globTail-owned tail blocked waiting for new lines
+	// after EOF; unused outside of TailGlob, which has no watcher of its
+	// own driving this tail directly.
+	notify chan struct{}
 }
 
 // NewTailFile starts tailing a file with opt options.
@@ -66,6 +145,12 @@ func NewTailFileWithOptions(filename string, opts Options) (*Tail, error) {
 		return nil, err
 	}
 
+	if opts.MustExist {
+		if _, err := os.Stat(filename); err != nil {
+			return nil, err
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	lines := make(chan *Line, linesCapacity)
 	errs := make(chan error, errorsCapacity)
@@ -80,17 +165,25 @@ func NewTailFileWithOptions(filename string, opts Options) (*Tail, error) {
 		cancel:   cancel,
 	}
 
+	seek := SeekInfo{Whence: io.SeekEnd}
+	pinned := false
+	if opts.Location != nil {
+		seek = *opts.Location
+		pinned = true
+	}
+
 	parent.wg.Add(1)
 	go func() {
 		defer parent.wg.Done()
-		parent.runFile(os.SEEK_END)
+		parent.runFile(seek, pinned)
 	}()
 	go parent.wait()
 
 	return parent, nil
 }
 
-// NewTailFile starts tailing a file with the default configuration.
+// NewTailFile starts tailing a file with the default configuration:
+// it follows the file forever, and keeps tailing across log rotation.
 func NewTailFile(filename string) (*Tail, error) {
 	return NewTailFileWithOptions(filename, Options{})
 }
@@ -114,10 +207,11 @@ func NewTailReaderWithOptions(reader io.Reader, opts Options) (*Tail, error) {
 		r:   reader,
 	}
 	t := &tail{
-		parent: parent,
-		reader: parent.newReader(r),
-		ctx:    ctx,
-		cancel: cancel,
+		parent:  parent,
+		reader:  parent.newReader(r),
+		limiter: newRateLimiter(opts),
+		ctx:     ctx,
+		cancel:  cancel,
 	}
 
 	parent.wg.Add(1)
@@ -150,16 +244,22 @@ func (t *Tail) wait() {
 
 // open opens the target file.
 // If it does not exist, wait for creating new file.
-func (t *Tail) open(seek int) (*tail, error) {
-	watcher, err := fsnotify.NewWatcher()
+// pinned, when true, keeps seek fixed across the retry loop below (e.g. a
+// caller-supplied Options.Location); otherwise seek falls back to
+// SeekStart once the file is found, since whatever initial position was
+// requested no longer makes sense after we've waited for the file to be
+// created.
+func (t *Tail) open(seek SeekInfo, pinned bool) (*tail, error) {
+	watcher, err := t.newWatcher()
 	if err != nil {
 		return nil, err
 	}
 	for {
-		file, err := os.Open(t.filename)
+		file, err := openFile(t.filename)
 		if err == nil {
 			// success, seek and watch the file.
-			if _, err := file.Seek(0, seek); err != nil {
+			offset, err := file.Seek(seek.Offset, seek.Whence)
+			if err != nil {
 				file.Close()
 				watcher.Close()
 				return nil, err
@@ -180,13 +280,17 @@ func (t *Tail) open(seek int) (*tail, error) {
 				file:    file,
 				reader:  t.newReader(r),
 				watcher: watcher,
+				limiter: newRateLimiter(t.opts),
+				offset:  offset,
 				ctx:     ctx,
 				cancel:  cancel,
 			}, nil
 		}
 
 		// fail. retry...
-		seek = io.SeekStart
+		if !pinned {
+			seek = SeekInfo{Whence: io.SeekStart}
+		}
 		timer := time.NewTimer(openRetryInterval)
 		select {
 		case <-t.ctx.Done():
@@ -207,8 +311,8 @@ func (t *Tail) newReader(r io.Reader) *bufio.Reader {
 }
 
 // runFile tails target files
-func (t *Tail) runFile(seek int) {
-	child, err := t.open(seek)
+func (t *Tail) runFile(seek SeekInfo, pinned bool) {
+	child, err := t.open(seek, pinned)
 	if err != nil {
 		if !errors.Is(err, context.Canceled) {
 			t.errors <- err
@@ -243,7 +347,7 @@ func (t *tail) runFile() {
 				}
 				return
 			}
-			err := t.tail()
+			err := t.readLines()
 			if err == nil {
 				continue
 			}
@@ -269,7 +373,7 @@ func (t *tail) runFile() {
 	var waiting bool // waiting for writing new lines?
 	for {
 		select {
-		case event := <-t.watcher.Events:
+		case event := <-t.watcher.Events():
 			if event.Op.Has(fsnotify.Remove) {
 				// the target file is removed, stop tailing.
 				return
@@ -277,12 +381,14 @@ func (t *tail) runFile() {
 			if event.Op.Has(fsnotify.Rename) {
 				// log rotation is detected.
 				if !renamed {
-					// start to watch creating new file.
-					t.parent.wg.Add(1)
-					go func() {
-						defer t.parent.wg.Done()
-						t.parent.runFile(io.SeekStart)
-					}()
+					if !t.parent.opts.NoReOpen {
+						// start to watch creating new file.
+						t.parent.wg.Add(1)
+						go func() {
+							defer t.parent.wg.Done()
+							t.parent.runFile(SeekInfo{Whence: io.SeekStart}, true)
+						}()
+					}
 
 					// wait a little, and stop tailing old file.
 					go func() {
@@ -314,12 +420,17 @@ func (t *tail) runFile() {
 			}
 		case err := <-cherr:
 			if errors.Is(err, io.EOF) {
+				if t.parent.opts.NoFollow {
+					// caught up with the file and not following: stop here,
+					// like plain cat.
+					return
+				}
 				waiting = true
 				continue
 			}
 			t.parent.errors <- err
 			return
-		case err := <-t.watcher.Errors:
+		case err := <-t.watcher.Errors():
 			t.parent.errors <- err
 			return
 		case <-t.ctx.Done():
@@ -331,7 +442,7 @@ func (t *tail) runFile() {
 // runReader tails io.Reader
 func (t *tail) runReader() {
 	defer t.cancel()
-	err := t.tail()
+	err := t.readLines()
 	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
 		return
 	}
@@ -359,16 +470,22 @@ func (t *tail) restrict() error {
 		if err != nil {
 			return err
 		}
+		t.offset = 0
+		t.lineNum = 0
 	}
 	return nil
 }
 
-// tail reads lines until EOF
-func (t *tail) tail() error {
+// readLines reads lines from t.file (or t.reader, for NewTailReader) until
+// EOF or an error, applying rate limiting and emitting each onto
+// t.parent.lines tagged with t.name. It is shared by the single-file path
+// and by globTail, which drives many of these concurrently.
+func (t *tail) readLines() error {
 	opts := t.parent.opts
 	for {
 		line, err := t.reader.ReadSlice('\n')
 		t.buf.Write(line)
+		t.offset += int64(len(line))
 		if errors.Is(err, bufio.ErrBufferFull) {
 			// the reader cannot find EOL in its buffer.
 			// continue to read a line.
@@ -378,7 +495,31 @@ func (t *tail) tail() error {
 		} else if err != nil {
 			return err
 		}
-		t.parent.lines <- &Line{t.buf.String(), time.Now()}
+		t.lineNum++
+		notice, emit, err := rateLimited(t.ctx, opts, t.limiter, t.buf.String())
+		if err != nil {
+			return err
+		}
+		if notice != "" {
+			select {
+			case t.parent.lines <- &Line{Text: notice, Time: time.Now(), Filename: t.name}:
+			case <-t.ctx.Done():
+				return t.ctx.Err()
+			}
+		}
+		if emit {
+			select {
+			case t.parent.lines <- &Line{
+				Text:     t.buf.String(),
+				Time:     time.Now(),
+				Filename: t.name,
+				Num:      t.lineNum,
+				SeekInfo: SeekInfo{Offset: t.offset, Whence: io.SeekStart},
+			}:
+			case <-t.ctx.Done():
+				return t.ctx.Err()
+			}
+		}
 		t.buf.Reset()
 	}
 }
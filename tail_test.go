@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -292,3 +293,240 @@ func writeFileAndClose(t *testing.T, file *os.File, line string) {
 		t.Error(err)
 	}
 }
+
+func TestTailFile_LineNumAndSeekInfo(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+	if err := os.WriteFile(filename, []byte("a\nbb\nccc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{
+		Location: &SeekInfo{Whence: io.SeekStart},
+		NoFollow: true,
+	}
+	tail, err := NewTailFileWithOptions(filename, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+
+	var lines []*Line
+	for line := range tail.Lines {
+		lines = append(lines, line)
+	}
+	for err := range tail.Errors {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		text   string
+		num    int
+		offset int64
+	}{
+		{"a\n", 1, 2},
+		{"bb\n", 2, 5},
+		{"ccc\n", 3, 9},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, w := range want {
+		if lines[i].Text != w.text || lines[i].Num != w.num || lines[i].SeekInfo.Offset != w.offset || lines[i].SeekInfo.Whence != io.SeekStart {
+			t.Errorf("line %d: got %+v, want text=%q num=%d offset=%d", i, lines[i], w.text, w.num, w.offset)
+		}
+	}
+}
+
+func TestTailFile_ResumeFromLocation(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+	if err := os.WriteFile(filename, []byte("a\nbb\nccc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// resume right after "bb\n": only "ccc\n" should be read, numbered
+	// from 1 again since Num counts lines within this tailing session,
+	// not within the file.
+	location := SeekInfo{Offset: 5, Whence: io.SeekStart}
+	tail, err := NewTailFileWithOptions(filename, Options{Location: &location, NoFollow: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+
+	var lines []*Line
+	for line := range tail.Lines {
+		lines = append(lines, line)
+	}
+	for err := range tail.Errors {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %+v", len(lines), lines)
+	}
+	if lines[0].Text != "ccc\n" || lines[0].Num != 1 {
+		t.Errorf("got %+v, want text=%q num=1", lines[0], "ccc\n")
+	}
+}
+
+func TestTailFile_LineNumResetsOnRotation(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := NewTailFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := file.WriteString("a\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString("b\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	recv := func() *Line {
+		t.Helper()
+		select {
+		case line := <-tail.Lines:
+			return line
+		case err := <-tail.Errors:
+			t.Fatalf("unexpected error: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a line")
+		}
+		return nil
+	}
+
+	got1, got2 := recv(), recv()
+	if got1.Num != 1 || got2.Num != 2 {
+		t.Fatalf("got Num %d, %d, want 1, 2", got1.Num, got2.Num)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filename, filename+".old"); err != nil {
+		t.Fatal(err)
+	}
+	file2, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file2.Close()
+	if _, err := file2.WriteString("c\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file2.WriteString("d\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	got3, got4 := recv(), recv()
+	if got3.Num != 1 || got4.Num != 2 {
+		t.Fatalf("got Num %d, %d, want 1, 2 (reset after rotation)", got3.Num, got4.Num)
+	}
+}
+
+func TestTailFile_MustExist(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "does-not-exist.log")
+
+	_, err := NewTailFileWithOptions(filename, Options{MustExist: true})
+	if err == nil {
+		t.Fatal("want an error, got nil")
+	}
+}
+
+func TestTailFile_NoFollow(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+	if err := os.WriteFile(filename, []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Location: &SeekInfo{Whence: io.SeekStart}, NoFollow: true}
+	tail, err := NewTailFileWithOptions(filename, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+
+	var got []string
+	for line := range tail.Lines {
+		got = append(got, line.Text)
+	}
+	for err := range tail.Errors {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if want := []string{"a\n", "b\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTailFile_NoReOpen(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := NewTailFileWithOptions(filename, Options{NoReOpen: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tail.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := file.WriteString("a\n"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case line := <-tail.Lines:
+		if line.Text != "a\n" {
+			t.Fatalf("got %q, want %q", line.Text, "a\n")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a line")
+	}
+
+	// rotating the file away, with NoReOpen set, should not start tailing
+	// the replacement file created in its place.
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filename, filename+".old"); err != nil {
+		t.Fatal(err)
+	}
+	file2, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file2.Close()
+	if _, err := file2.WriteString("b\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case line := <-tail.Lines:
+		t.Fatalf("got line %q from the replacement file, want NoReOpen to ignore it", line.Text)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -0,0 +1,176 @@
+package tail
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	fsnotify "github.com/fsnotify/fsnotify"
+)
+
+// defaultPollInterval is the polling interval used by pollingWatcher when
+// Options.PollInterval is not set but polling is selected anyway, e.g. by
+// defaultUsePolling on platforms where fsnotify's rename/remove semantics
+// are not reliable enough to drive the rotation state machine.
+const defaultPollInterval = time.Second
+
+// watcher abstracts the file-system notification backend used while tailing
+// a file, so that the rotation/truncation state machine in runFile can stay
+// the same regardless of how changes are detected.
+//
+// fsnotifyWatcher is backed by fsnotify (inotify/kqueue/ReadDirectoryChangesW)
+// and is the default on platforms where rename and remove events are
+// delivered reliably. pollingWatcher instead polls the watched path with
+// os.Stat, and is used on Windows, and whenever Options.PollInterval is set,
+// since NTFS (and network file systems such as NFS/SMB) do not reliably
+// deliver rename/remove notifications through fsnotify.
+type watcher interface {
+	Add(name string) error
+	Close() error
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+}
+
+// newWatcher selects the watcher backend for t according to Options.PollInterval
+// and the platform default.
+func (t *Tail) newWatcher() (watcher, error) {
+	interval := t.opts.PollInterval
+	if interval == 0 && defaultUsePolling {
+		interval = defaultPollInterval
+	}
+	if interval > 0 {
+		return newPollingWatcher(interval), nil
+	}
+	return newFsnotifyWatcher()
+}
+
+// fsnotifyWatcher adapts *fsnotify.Watcher to the watcher interface.
+type fsnotifyWatcher struct {
+	w *fsnotify.Watcher
+}
+
+func newFsnotifyWatcher() (*fsnotifyWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fsnotifyWatcher{w: w}, nil
+}
+
+func (w *fsnotifyWatcher) Add(name string) error         { return w.w.Add(name) }
+func (w *fsnotifyWatcher) Close() error                  { return w.w.Close() }
+func (w *fsnotifyWatcher) Events() <-chan fsnotify.Event { return w.w.Events }
+func (w *fsnotifyWatcher) Errors() <-chan error          { return w.w.Errors }
+
+// pollingWatcher implements watcher by periodically stat-ing the watched
+// path instead of relying on file-system change notifications. Writes are
+// detected by a change in size (growth, or shrinking on truncation), and
+// rename/remove by the path no longer
+// resolving to the same underlying file, as reported by os.SameFile.
+type pollingWatcher struct {
+	interval time.Duration
+	events   chan fsnotify.Event
+	errs     chan error
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	watch map[string]os.FileInfo
+}
+
+func newPollingWatcher(interval time.Duration) *pollingWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pollingWatcher{
+		interval: interval,
+		events:   make(chan fsnotify.Event),
+		errs:     make(chan error),
+		ctx:      ctx,
+		cancel:   cancel,
+		watch:    make(map[string]os.FileInfo),
+	}
+}
+
+func (w *pollingWatcher) Add(name string) error {
+	stat, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	_, exists := w.watch[name]
+	w.watch[name] = stat
+	w.mu.Unlock()
+	if !exists {
+		w.wg.Add(1)
+		go w.poll(name)
+	}
+	return nil
+}
+
+func (w *pollingWatcher) poll(name string) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.ctx.Done():
+			return
+		}
+
+		w.mu.Lock()
+		prev, ok := w.watch[name]
+		w.mu.Unlock()
+		if !ok {
+			return
+		}
+
+		stat, err := os.Stat(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				w.send(fsnotify.Event{Name: name, Op: fsnotify.Remove})
+				w.mu.Lock()
+				delete(w.watch, name)
+				w.mu.Unlock()
+				return
+			}
+			select {
+			case w.errs <- err:
+			case <-w.ctx.Done():
+				return
+			}
+			continue
+		}
+
+		if !os.SameFile(prev, stat) {
+			// the path now refers to a different file: a rotator renamed
+			// the old file away and created a new one in its place.
+			w.send(fsnotify.Event{Name: name, Op: fsnotify.Rename})
+		} else if stat.Size() != prev.Size() {
+			w.send(fsnotify.Event{Name: name, Op: fsnotify.Write})
+		}
+
+		w.mu.Lock()
+		w.watch[name] = stat
+		w.mu.Unlock()
+	}
+}
+
+func (w *pollingWatcher) send(ev fsnotify.Event) {
+	select {
+	case w.events <- ev:
+	case <-w.ctx.Done():
+	}
+}
+
+func (w *pollingWatcher) Close() error {
+	w.cancel()
+	w.wg.Wait()
+	close(w.events)
+	close(w.errs)
+	return nil
+}
+
+func (w *pollingWatcher) Events() <-chan fsnotify.Event { return w.events }
+func (w *pollingWatcher) Errors() <-chan error          { return w.errs }
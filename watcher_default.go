@@ -0,0 +1,8 @@
+//go:build !windows
+// +build !windows
+
+package tail
+
+// defaultUsePolling is false on platforms where fsnotify's rename/remove
+// events are reliable enough to drive the rotation state machine directly.
+const defaultUsePolling = false
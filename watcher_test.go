@@ -0,0 +1,75 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fsnotify "github.com/fsnotify/fsnotify"
+)
+
+func TestPollingWatcher(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+	filename := filepath.Join(tmpdir, "test.log")
+	if err := os.WriteFile(filename, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := newPollingWatcher(10 * time.Millisecond)
+	defer w.Close()
+	if err := w.Add(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waitEvent := func(want fsnotify.Op) {
+		t.Helper()
+		timer := time.NewTimer(2 * time.Second)
+		defer timer.Stop()
+		select {
+		case event := <-w.Events():
+			if !event.Op.Has(want) {
+				t.Errorf("got op %v, want %v", event.Op, want)
+			}
+		case err := <-w.Errors():
+			t.Fatalf("unexpected error: %v", err)
+		case <-timer.C:
+			t.Fatalf("timed out waiting for %v", want)
+		}
+	}
+
+	// writing more data grows the file: a Write event.
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("world\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	waitEvent(fsnotify.Write)
+
+	// truncating in place shrinks the file without changing its identity:
+	// still a Write event, leaving restrict() to notice the truncation.
+	if err := os.Truncate(filename, 0); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(fsnotify.Write)
+
+	// renaming the file away and creating a new one in its place changes
+	// os.SameFile's answer: a Rename event.
+	if err := os.Rename(filename, filename+".old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(fsnotify.Rename)
+
+	// removing the file entirely: a Remove event.
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(fsnotify.Remove)
+}
@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package tail
+
+// defaultUsePolling is true on Windows, since NTFS does not reliably
+// deliver rename/remove notifications through fsnotify's
+// ReadDirectoryChangesW backend.
+const defaultUsePolling = true